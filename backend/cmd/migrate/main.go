@@ -0,0 +1,125 @@
+// Command migrate wraps the internal/migrate package behind CLI subcommands
+// so disaster recovery (rollback, jump to a version, force out of a dirty
+// state) doesn't require a second migration tool or shelling into the DB.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/migrate"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	cmd := os.Args[1]
+	args := os.Args[2:]
+
+	switch cmd {
+	case "up":
+		err = migrate.Up(ctx, pool)
+	case "down":
+		steps, parseErr := requireIntArg(args, "down")
+		if parseErr != nil {
+			err = parseErr
+			break
+		}
+		err = migrate.Down(ctx, pool, steps)
+	case "goto":
+		version, parseErr := requireIntArg(args, "goto")
+		if parseErr != nil {
+			err = parseErr
+			break
+		}
+		err = migrate.Goto(ctx, pool, uint(version))
+	case "force":
+		version, parseErr := requireIntArg(args, "force")
+		if parseErr != nil {
+			err = parseErr
+			break
+		}
+		err = migrate.Force(ctx, pool, version)
+	case "status":
+		err = runStatus(ctx, pool)
+	case "version":
+		err = runVersion(ctx, pool)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		slog.Error("migrate command failed", "command", cmd, "error", err)
+		os.Exit(1)
+	}
+}
+
+func requireIntArg(args []string, cmd string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s requires exactly one numeric argument", cmd)
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q", cmd, args[0])
+	}
+	return n, nil
+}
+
+func runStatus(ctx context.Context, pool *pgxpool.Pool) error {
+	current, dirty, pending, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d (dirty: %t)\n", current, dirty)
+	if len(pending) == 0 {
+		fmt.Println("pending migrations: none")
+		return nil
+	}
+	fmt.Printf("pending migrations: %v\n", pending)
+	return nil
+}
+
+func runVersion(ctx context.Context, pool *pgxpool.Pool) error {
+	current, dirty, _, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d (dirty: %t)\n", current, dirty)
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: migrate <command> [args]
+
+commands:
+  up              apply all pending migrations
+  down N          roll back N migrations
+  goto V          migrate to version V, up or down as needed
+  force V         set the recorded version to V without running migrations
+  status          print current version, dirty flag, and pending migrations
+  version         print current version and dirty flag`)
+}
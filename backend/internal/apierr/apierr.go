@@ -0,0 +1,89 @@
+// Package apierr defines a single typed error shape for Fiber handlers to
+// return, plus a Fiber ErrorHandler that renders it as a stable JSON
+// envelope. Handlers should prefer `return apierr.BadRequest(...)` style
+// returns over hand-rolling `c.Status(...).JSON(fiber.Map{...})` so every
+// endpoint produces the same error shape and every 5xx gets a correlated
+// log line.
+package apierr
+
+import (
+	"net/http"
+)
+
+// Error is the typed API error returned by handlers. It implements the
+// standard `error` interface so it can flow through normal Go error
+// handling and still be rendered with full fidelity by the Fiber
+// ErrorHandler.
+type Error struct {
+	Code       string         `json:"code"`
+	HTTPStatus int            `json:"-"`
+	Message    string         `json:"message"`
+	Details    map[string]any `json:"details,omitempty"`
+	Cause      error          `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// WithDetails returns a copy of e with Details set, for call sites that want
+// to attach structured context (e.g. a field name) without a new
+// constructor.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+func newError(status int, code, message string, cause error) *Error {
+	return &Error{Code: code, HTTPStatus: status, Message: message, Cause: cause}
+}
+
+// BadRequest builds a 400 with the given machine-readable code and
+// human-readable message.
+func BadRequest(code, message string) *Error {
+	return newError(http.StatusBadRequest, code, message, nil)
+}
+
+// Unauthorized builds a 401.
+func Unauthorized(code, message string) *Error {
+	return newError(http.StatusUnauthorized, code, message, nil)
+}
+
+// Forbidden builds a 403.
+func Forbidden(code, message string) *Error {
+	return newError(http.StatusForbidden, code, message, nil)
+}
+
+// NotFound builds a 404.
+func NotFound(code, message string) *Error {
+	return newError(http.StatusNotFound, code, message, nil)
+}
+
+// Conflict builds a 409.
+func Conflict(code, message string) *Error {
+	return newError(http.StatusConflict, code, message, nil)
+}
+
+// Internal builds a 500 wrapping an underlying cause, which is logged but
+// never rendered to the client.
+func Internal(code, message string, cause error) *Error {
+	return newError(http.StatusInternalServerError, code, message, cause)
+}
+
+// Upstream builds a 502 for failures talking to a third-party dependency
+// (e.g. the GitHub API), wrapping the underlying cause.
+func Upstream(code string, cause error) *Error {
+	return newError(http.StatusBadGateway, code, "upstream request failed", cause)
+}
+
+// Unavailable builds a 503, used for missing/unconfigured dependencies
+// (DB pool not set up, encryption key missing, etc).
+func Unavailable(code, message string) *Error {
+	return newError(http.StatusServiceUnavailable, code, message, nil)
+}
@@ -0,0 +1,62 @@
+package apierr
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// envelope is the stable shape every error response renders as.
+type envelope struct {
+	Error envelopeError `json:"error"`
+}
+
+type envelopeError struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// Handler is a Fiber ErrorHandler that renders any error returned by a
+// handler (typed *apierr.Error or not) as the stable envelope, and logs the
+// underlying cause at warn/error with the request ID attached for
+// correlation. Register it on fiber.Config{ErrorHandler: apierr.Handler}.
+func Handler(c *fiber.Ctx, err error) error {
+	requestID := c.Get("X-Request-ID")
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			apiErr = newError(fiberErr.Code, "unexpected_error", fiberErr.Message, err)
+		} else {
+			apiErr = Internal("internal_error", "an unexpected error occurred", err)
+		}
+	}
+
+	logAttrs := []any{
+		"code", apiErr.Code,
+		"status", apiErr.HTTPStatus,
+		"request_id", requestID,
+		"path", c.Path(),
+		"method", c.Method(),
+	}
+	if apiErr.Cause != nil {
+		logAttrs = append(logAttrs, "error", apiErr.Cause)
+	}
+	if apiErr.HTTPStatus >= http.StatusInternalServerError {
+		slog.Error("request failed", logAttrs...)
+	} else {
+		slog.Warn("request rejected", logAttrs...)
+	}
+
+	return c.Status(apiErr.HTTPStatus).JSON(envelope{Error: envelopeError{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: requestID,
+	}})
+}
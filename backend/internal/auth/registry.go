@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WalletVerifier is the contract a chain implementation must satisfy to
+// plug into wallet-signature login. Each chain self-registers an instance
+// via init() in its own file under auth/verifiers, so adding support for a
+// new chain never requires touching the handler or this package.
+type WalletVerifier interface {
+	// Type returns the canonical wallet type this verifier handles, e.g.
+	// "evm" or "solana". Must match what NormalizeWalletType returns.
+	Type() string
+	// NormalizeAddress validates and canonicalizes an address for this
+	// chain (e.g. checksum-casing an EVM address).
+	NormalizeAddress(address string) (string, error)
+	// CanonicalMessage returns the current login message format signed by
+	// up-to-date clients for the given nonce.
+	CanonicalMessage(nonce string) string
+	// LegacyMessages returns prior message formats that should still be
+	// accepted, newest first, so older or slow-to-update signing clients
+	// aren't locked out.
+	LegacyMessages(nonce string) []string
+	// Verify checks that signature is a valid signature of message by
+	// address, using publicKey when the chain needs more than the address.
+	// The built-in EVM and Solana verifiers ignore publicKey.
+	Verify(address, message, signature, publicKey string) error
+}
+
+var registry = map[string]WalletVerifier{}
+
+// RegisterVerifier adds v to the process-wide registry, keyed by its
+// canonical Type(). Intended to be called from an init() func in each
+// verifier's own file; panics on duplicate registration since that always
+// indicates a programming error, not a runtime condition.
+func RegisterVerifier(v WalletVerifier) {
+	t := v.Type()
+	if _, exists := registry[t]; exists {
+		panic(fmt.Sprintf("auth: verifier for wallet type %q registered twice", t))
+	}
+	registry[t] = v
+}
+
+// GetVerifier looks up the registered verifier for a canonical wallet type.
+func GetVerifier(walletType string) (WalletVerifier, error) {
+	v, ok := registry[walletType]
+	if !ok {
+		return nil, fmt.Errorf("no verifier registered for wallet type %q", walletType)
+	}
+	return v, nil
+}
+
+// NormalizeWalletType validates and lower-cases a client-supplied wallet
+// type against whatever verifiers have self-registered, so a new chain's
+// verifiers/*.go file is the only edit needed to make it acceptable here
+// too - this package never lists chains itself.
+func NormalizeWalletType(walletType string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(walletType))
+	if _, ok := registry[t]; !ok {
+		return "", fmt.Errorf("unsupported wallet type %q", walletType)
+	}
+	return t, nil
+}
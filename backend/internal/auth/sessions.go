@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired,
+// or otherwise cannot be exchanged.
+var ErrInvalidRefreshToken = errors.New("invalid_refresh_token")
+
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated (or revoked) is presented again. The caller should treat this as a
+// compromise signal: the whole session chain has been revoked by the time
+// this error is returned.
+var ErrRefreshTokenReused = errors.New("refresh_token_reused")
+
+// Session mirrors a row in the sessions table.
+type Session struct {
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	WalletID         uuid.UUID  `json:"wallet_id"`
+	RefreshTokenHash string     `json:"-"`
+	ParentID         *uuid.UUID `json:"parent_id,omitempty"`
+	UserAgent        string     `json:"user_agent,omitempty"`
+	IP               string     `json:"ip,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RefreshTokenTTL is how long a refresh token is valid before it must be
+// rotated or re-issued via a fresh login.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// newRefreshToken returns an opaque, URL-safe refresh token and its stored
+// hash. Only the hash is ever persisted.
+func newRefreshToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession starts a new session chain (parent_id is nil) for the given
+// user/wallet and returns the opaque refresh token to hand back to the
+// client.
+func CreateSession(ctx context.Context, pool *pgxpool.Pool, userID, walletID uuid.UUID, userAgent, ip string) (string, Session, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", Session{}, err
+	}
+
+	var s Session
+	err = pool.QueryRow(ctx, `
+INSERT INTO sessions (user_id, wallet_id, refresh_token_hash, parent_id, user_agent, ip, created_at, expires_at)
+VALUES ($1, $2, $3, NULL, $4, $5, now(), now() + $6::interval)
+RETURNING id, user_id, wallet_id, parent_id, user_agent, ip, created_at, expires_at, revoked_at
+`, userID, walletID, hash, userAgent, ip, RefreshTokenTTL.String()).Scan(
+		&s.ID, &s.UserID, &s.WalletID, &s.ParentID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt,
+	)
+	if err != nil {
+		return "", Session{}, fmt.Errorf("create session: %w", err)
+	}
+	s.RefreshTokenHash = hash
+	return token, s, nil
+}
+
+// RotateSession exchanges a refresh token for a new one, linking the new
+// session to the old one via parent_id and revoking the old one. If the
+// presented token was already revoked (i.e. it has been used before), this
+// is treated as reuse: the entire chain is revoked and ErrRefreshTokenReused
+// is returned so the caller can force the user to re-authenticate.
+func RotateSession(ctx context.Context, pool *pgxpool.Pool, refreshToken, userAgent, ip string) (string, Session, error) {
+	hash := hashRefreshToken(refreshToken)
+
+	var cur Session
+	err := pool.QueryRow(ctx, `
+SELECT id, user_id, wallet_id, parent_id, user_agent, ip, created_at, expires_at, revoked_at
+FROM sessions
+WHERE refresh_token_hash = $1
+`, hash).Scan(&cur.ID, &cur.UserID, &cur.WalletID, &cur.ParentID, &cur.UserAgent, &cur.IP, &cur.CreatedAt, &cur.ExpiresAt, &cur.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", Session{}, ErrInvalidRefreshToken
+		}
+		return "", Session{}, fmt.Errorf("lookup session: %w", err)
+	}
+
+	if time.Now().After(cur.ExpiresAt) {
+		return "", Session{}, ErrInvalidRefreshToken
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return "", Session{}, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", Session{}, fmt.Errorf("begin rotate tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Revoke the presented token only if it's still live, and let the
+	// conditional UPDATE itself (not the SELECT above) decide whether this
+	// is a legitimate rotation or reuse: two concurrent requests for the
+	// same token both race here, but only one can flip revoked_at from NULL
+	// to now() inside this transaction. The loser sees zero rows affected -
+	// whether because it lost the race or because the token was already
+	// revoked by an earlier rotation/logout - and that's treated as reuse.
+	tag, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, cur.ID)
+	if err != nil {
+		return "", Session{}, fmt.Errorf("revoke old session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Presenting an already-revoked token means it leaked somewhere
+		// along the way - revoke the whole chain so the attacker and the
+		// legitimate user are both logged out.
+		if revokeErr := RevokeChain(ctx, pool, cur.ID); revokeErr != nil {
+			return "", Session{}, fmt.Errorf("revoke chain after reuse: %w", revokeErr)
+		}
+		return "", Session{}, ErrRefreshTokenReused
+	}
+
+	var next Session
+	if err := tx.QueryRow(ctx, `
+INSERT INTO sessions (user_id, wallet_id, refresh_token_hash, parent_id, user_agent, ip, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, now(), now() + $7::interval)
+RETURNING id, user_id, wallet_id, parent_id, user_agent, ip, created_at, expires_at, revoked_at
+`, cur.UserID, cur.WalletID, newHash, cur.ID, userAgent, ip, RefreshTokenTTL.String()).Scan(
+		&next.ID, &next.UserID, &next.WalletID, &next.ParentID, &next.UserAgent, &next.IP, &next.CreatedAt, &next.ExpiresAt, &next.RevokedAt,
+	); err != nil {
+		return "", Session{}, fmt.Errorf("insert rotated session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", Session{}, fmt.Errorf("commit rotate tx: %w", err)
+	}
+
+	next.RefreshTokenHash = newHash
+	return newToken, next, nil
+}
+
+// RevokeChain revokes every session reachable from sessionID by walking
+// parent_id links in both directions (the session it descended from and any
+// sessions descended from it), so a single reuse detection invalidates the
+// whole refresh-token lineage.
+func RevokeChain(ctx context.Context, pool *pgxpool.Pool, sessionID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `
+WITH RECURSIVE chain AS (
+    SELECT id, parent_id FROM sessions WHERE id = $1
+    UNION
+    SELECT s.id, s.parent_id FROM sessions s JOIN chain c ON s.parent_id = c.id OR s.id = c.parent_id
+)
+UPDATE sessions SET revoked_at = now()
+WHERE id IN (SELECT id FROM chain) AND revoked_at IS NULL
+`, sessionID)
+	if err != nil {
+		return fmt.Errorf("revoke chain: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every session belonging to a user, regardless of
+// chain, used by "log out everywhere".
+func RevokeAllForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `
+UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL
+`, userID)
+	if err != nil {
+		return fmt.Errorf("revoke all sessions for user: %w", err)
+	}
+	return nil
+}
+
+// SessionIdentity is the minimal set of claims needed to re-issue an access
+// JWT for a rotated session, without pulling in the full user/wallet row
+// shapes used by the nonce-verification flow.
+type SessionIdentity struct {
+	UserID     uuid.UUID
+	Role       string
+	WalletType string
+	Address    string
+}
+
+// LoadSessionIdentity reloads the user and wallet fields backing a session,
+// used by the refresh endpoint to re-issue an access JWT without requiring
+// the client to resend credentials.
+func LoadSessionIdentity(ctx context.Context, pool *pgxpool.Pool, userID, walletID uuid.UUID) (SessionIdentity, error) {
+	var id SessionIdentity
+	id.UserID = userID
+	if err := pool.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&id.Role); err != nil {
+		return SessionIdentity{}, fmt.Errorf("load user: %w", err)
+	}
+	if err := pool.QueryRow(ctx, `SELECT wallet_type, address FROM wallets WHERE id = $1`, walletID).Scan(&id.WalletType, &id.Address); err != nil {
+		return SessionIdentity{}, fmt.Errorf("load wallet: %w", err)
+	}
+	return id, nil
+}
+
+// SessionByRefreshToken looks up the session a raw refresh token belongs to,
+// used by logout to know which chain to revoke without rotating it.
+func SessionByRefreshToken(ctx context.Context, pool *pgxpool.Pool, refreshToken string) (Session, error) {
+	hash := hashRefreshToken(refreshToken)
+	var s Session
+	err := pool.QueryRow(ctx, `
+SELECT id, user_id, wallet_id, parent_id, user_agent, ip, created_at, expires_at, revoked_at
+FROM sessions
+WHERE refresh_token_hash = $1
+`, hash).Scan(&s.ID, &s.UserID, &s.WalletID, &s.ParentID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Session{}, ErrInvalidRefreshToken
+		}
+		return Session{}, fmt.Errorf("lookup session: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// sessionsTestPool connects to the Postgres instance named by
+// DATABASE_URL_TEST (migrated with backend/migrations) and seeds a user and
+// wallet row for the caller to build sessions against. Skipped when no test
+// database is configured, since session rotation is written directly
+// against SQL rather than behind a mockable interface.
+func sessionsTestPool(t *testing.T) (*pgxpool.Pool, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	dsn := os.Getenv("DATABASE_URL_TEST")
+	if dsn == "" {
+		t.Skip("DATABASE_URL_TEST not set; skipping sessions integration test")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	var userID uuid.UUID
+	if err := pool.QueryRow(ctx,
+		`INSERT INTO users (role) VALUES ('member') RETURNING id`,
+	).Scan(&userID); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+
+	var walletID uuid.UUID
+	if err := pool.QueryRow(ctx,
+		`INSERT INTO wallets (user_id, wallet_type, address) VALUES ($1, 'evm', $2) RETURNING id`,
+		userID, "0x"+strings.ReplaceAll(uuid.NewString()+uuid.NewString(), "-", "")[:40],
+	).Scan(&walletID); err != nil {
+		t.Fatalf("seed wallet: %v", err)
+	}
+
+	return pool, userID, walletID
+}
+
+func sessionRevoked(t *testing.T, pool *pgxpool.Pool, id uuid.UUID) bool {
+	t.Helper()
+	var revoked bool
+	if err := pool.QueryRow(context.Background(),
+		`SELECT revoked_at IS NOT NULL FROM sessions WHERE id = $1`, id,
+	).Scan(&revoked); err != nil {
+		t.Fatalf("load session %s: %v", id, err)
+	}
+	return revoked
+}
+
+// TestRotateSession_ReuseRevokesChain verifies the reuse-detection path: once
+// a refresh token has been rotated away, presenting it again must report
+// ErrRefreshTokenReused and revoke every session in the chain, not just the
+// reused token itself.
+func TestRotateSession_ReuseRevokesChain(t *testing.T) {
+	pool, userID, walletID := sessionsTestPool(t)
+	ctx := context.Background()
+
+	firstToken, first, err := CreateSession(ctx, pool, userID, walletID, "ua", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	secondToken, second, err := RotateSession(ctx, pool, firstToken, "ua", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("first rotation should succeed: %v", err)
+	}
+	if sessionRevoked(t, pool, first.ID) != true {
+		t.Errorf("original session should be revoked after rotation")
+	}
+	if sessionRevoked(t, pool, second.ID) {
+		t.Errorf("rotated session should not be revoked yet")
+	}
+
+	if _, _, err := RotateSession(ctx, pool, firstToken, "ua", "1.2.3.4"); err != ErrRefreshTokenReused {
+		t.Fatalf("reusing a rotated token should return ErrRefreshTokenReused, got %v", err)
+	}
+	if !sessionRevoked(t, pool, second.ID) {
+		t.Errorf("reuse of the old token should revoke the rotated child session too")
+	}
+
+	// The chain is now fully revoked, so even the latest token is dead.
+	if _, _, err := RotateSession(ctx, pool, secondToken, "ua", "1.2.3.4"); err != ErrRefreshTokenReused {
+		t.Errorf("rotating from a revoked chain should also report reuse, got %v", err)
+	}
+}
+
+// TestRevokeChain_WalksBothDirections verifies RevokeChain revokes every
+// session in a lineage regardless of which link it's called on: ancestors
+// reached via parent_id and descendants reached via the reverse walk.
+func TestRevokeChain_WalksBothDirections(t *testing.T) {
+	pool, userID, walletID := sessionsTestPool(t)
+	ctx := context.Background()
+
+	rootToken, root, err := CreateSession(ctx, pool, userID, walletID, "ua", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("create root session: %v", err)
+	}
+	childToken, child, err := RotateSession(ctx, pool, rootToken, "ua", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("rotate to child session: %v", err)
+	}
+	_, grandchild, err := RotateSession(ctx, pool, childToken, "ua", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("rotate to grandchild session: %v", err)
+	}
+
+	if err := RevokeChain(ctx, pool, child.ID); err != nil {
+		t.Fatalf("revoke chain from middle link: %v", err)
+	}
+
+	if !sessionRevoked(t, pool, root.ID) {
+		t.Errorf("revoking from the middle session should also revoke its ancestor")
+	}
+	if !sessionRevoked(t, pool, child.ID) {
+		t.Errorf("revoking from the middle session should revoke itself")
+	}
+	if !sessionRevoked(t, pool, grandchild.ID) {
+		t.Errorf("revoking from the middle session should also revoke its descendant")
+	}
+}
@@ -0,0 +1,76 @@
+package verifiers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+const solanaType = "solana"
+
+type solanaVerifier struct{}
+
+func (solanaVerifier) Type() string { return solanaType }
+
+// NormalizeAddress validates that a Solana address decodes to a 32-byte
+// ed25519 public key and returns it re-encoded in canonical base58 form.
+func (solanaVerifier) NormalizeAddress(address string) (string, error) {
+	pub, err := base58.Decode(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid solana address %q: %w", address, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid solana address %q: want %d bytes, got %d", address, ed25519.PublicKeySize, len(pub))
+	}
+	return base58.Encode(pub), nil
+}
+
+func (solanaVerifier) CanonicalMessage(nonce string) string {
+	return auth.LoginMessage(nonce)
+}
+
+func (solanaVerifier) LegacyMessages(nonce string) []string {
+	return []string{auth.LegacyLoginMessage(nonce)}
+}
+
+// Verify checks an ed25519 signature of message by address. Solana wallets
+// sign the raw UTF-8 message bytes directly, unlike EVM's personal_sign
+// there is no prefix or hash wrapping.
+func (solanaVerifier) Verify(address, message, signature, _ string) error {
+	pub, err := base58.Decode(address)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid solana address %q", address)
+	}
+	sig, err := decodeSolanaSignature(signature)
+	if err != nil {
+		return fmt.Errorf("decode solana signature: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), []byte(message), sig) {
+		return fmt.Errorf("solana signature does not match address %q", address)
+	}
+	return nil
+}
+
+// decodeSolanaSignature accepts either the base58 or base64 encoding
+// different wallet adapters use for the 64-byte ed25519 signature.
+func decodeSolanaSignature(signature string) ([]byte, error) {
+	if sig, err := base58.Decode(signature); err == nil && len(sig) == ed25519.SignatureSize {
+		return sig, nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("want %d bytes, got %d", ed25519.SignatureSize, len(sig))
+	}
+	return sig, nil
+}
+
+func init() {
+	auth.RegisterVerifier(solanaVerifier{})
+}
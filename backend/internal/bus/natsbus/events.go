@@ -0,0 +1,222 @@
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+// StreamName is the single JetStream stream backing every domain event
+// published by the API. Keeping one stream per service (rather than one per
+// subject) keeps retention/ack policy configuration in one place.
+const StreamName = "grainlify"
+
+// streamSubjects are the wildcard subjects the grainlify stream captures.
+// Each uses the multi-token `>` wildcard rather than `*` since published
+// subjects vary in token count (e.g. "auth.user.logged_in" has three
+// tokens, "issue_application.created" has two) and `*` only ever matches
+// one. Add a new `>` wildcard here when a new domain starts publishing
+// events.
+var streamSubjects = []string{"auth.>", "issue_application.>", "github.>"}
+
+// StreamConfig controls the retention policy applied when the grainlify
+// JetStream stream is created. Zero values fall back to sane defaults in
+// ensureStream.
+type StreamConfig struct {
+	// MaxAge is how long an event is retained before JetStream drops it.
+	// Defaults to 7 days.
+	MaxAge time.Duration
+	// Retention is the JetStream retention policy. Defaults to LimitsPolicy.
+	Retention nats.RetentionPolicy
+}
+
+// Event is the CloudEvents-style envelope every domain event is published
+// as. `Data` is left as `any` so callers can pass any JSON-marshalable
+// payload struct.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Subject string    `json:"subject"`
+	Source  string    `json:"source"`
+	Data    any       `json:"data"`
+}
+
+// eventSource identifies this service as the origin of every event it
+// publishes.
+const eventSource = "grainlify-api"
+
+// fetchErrorBackoff is how long Subscribe's fetch loop waits after a
+// non-timeout Fetch error before retrying.
+const fetchErrorBackoff = 2 * time.Second
+
+// subjectMatches reports whether subject falls under a NATS subject filter
+// that may use the `*` (exactly one token) and `>` (one or more trailing
+// tokens) wildcards. It mirrors the matching JetStream itself applies when
+// deciding which stream a published subject belongs to.
+func subjectMatches(filter, subject string) bool {
+	filterTokens := strings.Split(filter, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, ft := range filterTokens {
+		if ft == ">" {
+			return i < len(subjectTokens)
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if ft != "*" && ft != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(filterTokens) == len(subjectTokens)
+}
+
+// SubjectCoveredByStream reports whether subject matches one of the
+// grainlify stream's declared subject filters. Callers that subscribe with a
+// narrower filter (e.g. "github.issue.*") can use this to assert their
+// filter sits within the stream's subject space before relying on it.
+func SubjectCoveredByStream(subject string) bool {
+	for _, f := range streamSubjects {
+		if subjectMatches(f, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureStream creates the grainlify JetStream stream if it doesn't already
+// exist. Safe to call every time Connect runs; it's a no-op once the stream
+// is present.
+func ensureStream(js nats.JetStreamContext, cfg StreamConfig) error {
+	maxAge := cfg.MaxAge
+	if maxAge == 0 {
+		maxAge = 7 * 24 * time.Hour
+	}
+	retention := cfg.Retention
+	if retention == 0 {
+		retention = nats.LimitsPolicy
+	}
+
+	_, err := js.StreamInfo(StreamName)
+	if err == nil {
+		return nil
+	}
+	if err != nats.ErrStreamNotFound {
+		return fmt.Errorf("lookup stream %q: %w", StreamName, err)
+	}
+
+	slog.Info("creating JetStream stream", "stream", StreamName, "subjects", streamSubjects, "max_age", maxAge)
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:      StreamName,
+		Subjects:  streamSubjects,
+		Retention: retention,
+		MaxAge:    maxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("create stream %q: %w", StreamName, err)
+	}
+	return nil
+}
+
+// PublishEvent encodes data as a CloudEvents-style JSON envelope and
+// publishes it to subject on the grainlify stream. Publishing is
+// asynchronous: the call returns once NATS has accepted the message for
+// delivery, and the ack is awaited in the background so a slow JetStream ack
+// never adds latency to the request that triggered the event. Ack failures
+// are logged at error level since by then the caller has already moved on.
+func (b *Bus) PublishEvent(ctx context.Context, subject string, eventType string, data any) error {
+	if b == nil || b.js == nil {
+		return fmt.Errorf("nats jetstream not connected")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	evt := Event{
+		ID:      uuid.NewString(),
+		Type:    eventType,
+		Time:    time.Now().UTC(),
+		Subject: subject,
+		Source:  eventSource,
+		Data:    data,
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	future, err := b.js.PublishAsync(subject, payload)
+	if err != nil {
+		return fmt.Errorf("publish event to %q: %w", subject, err)
+	}
+
+	go func() {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			slog.Error("jetstream publish ack failed", "subject", subject, "event_id", evt.ID, "error", err)
+		case <-time.After(5 * time.Second):
+			slog.Error("jetstream publish ack timed out", "subject", subject, "event_id", evt.ID)
+		}
+	}()
+
+	return nil
+}
+
+// Subscribe registers a durable JetStream pull consumer on subject and hands
+// each delivered message to handler. Messages are acked only after handler
+// returns nil, so a handler error leaves the message pending for redelivery.
+// The returned function stops the consumer.
+func (b *Bus) Subscribe(subject, durable string, handler func(context.Context, []byte) error) (func(), error) {
+	if b == nil || b.js == nil {
+		return nil, fmt.Errorf("nats jetstream not connected")
+	}
+
+	sub, err := b.js.PullSubscribe(subject, durable)
+	if err != nil {
+		return nil, fmt.Errorf("pull subscribe %q durable %q: %w", subject, durable, err)
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			msgs, err := sub.Fetch(1, nats.MaxWait(5*time.Second))
+			if err != nil {
+				if err != nats.ErrTimeout {
+					slog.Warn("jetstream fetch failed", "subject", subject, "durable", durable, "error", err)
+					// Back off before retrying so a persistent failure (consumer
+					// deleted, permission error, etc.) doesn't busy-spin this
+					// goroutine and flood the logs.
+					time.Sleep(fetchErrorBackoff)
+				}
+				continue
+			}
+			for _, msg := range msgs {
+				if err := handler(context.Background(), msg.Data); err != nil {
+					slog.Warn("jetstream handler failed, leaving message for redelivery",
+						"subject", subject, "durable", durable, "error", err)
+					continue
+				}
+				if err := msg.Ack(); err != nil {
+					slog.Warn("jetstream ack failed", "subject", subject, "durable", durable, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() { close(stop) }, nil
+}
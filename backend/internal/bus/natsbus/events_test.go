@@ -0,0 +1,22 @@
+package natsbus
+
+import "testing"
+
+// TestStreamSubjectsCoverPublishedSubjects guards against the stream
+// filters silently drifting out of sync with the subjects handlers actually
+// publish to: if a published subject falls outside streamSubjects, its
+// PublishAsync ack future never resolves and the event is dropped.
+func TestStreamSubjectsCoverPublishedSubjects(t *testing.T) {
+	published := []string{
+		"auth.user.logged_in",
+		"issue_application.created",
+		"github.issue.updated",
+		"github.issue.comment_created",
+	}
+
+	for _, subject := range published {
+		if !SubjectCoveredByStream(subject) {
+			t.Errorf("published subject %q does not match any stream subject in %v", subject, streamSubjects)
+		}
+	}
+}
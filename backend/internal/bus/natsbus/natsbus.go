@@ -11,6 +11,7 @@ import (
 
 type Bus struct {
 	nc *nats.Conn
+	js nats.JetStreamContext
 }
 
 func Connect(url string) (*Bus, error) {
@@ -45,8 +46,18 @@ func Connect(url string) (*Bus, error) {
 		"status", nc.Status().String(),
 		"connected_url", nc.ConnectedUrl(),
 	)
-	
-	return &Bus{nc: nc}, nil
+
+	js, err := nc.JetStream()
+	if err != nil {
+		slog.Error("failed to acquire JetStream context", "error", err)
+		return nil, err
+	}
+	if err := ensureStream(js, StreamConfig{}); err != nil {
+		slog.Error("failed to ensure JetStream stream", "error", err)
+		return nil, err
+	}
+
+	return &Bus{nc: nc, js: js}, nil
 }
 
 // maskNATSURL masks credentials in NATS URL for logging
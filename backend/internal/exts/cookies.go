@@ -0,0 +1,51 @@
+// Package exts holds small Fiber extensions shared across handlers that
+// don't belong in any single domain package.
+package exts
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RefreshCookieName is the cookie used to carry the opaque refresh token for
+// browser clients that would rather not touch localStorage.
+const RefreshCookieName = "grainlify_refresh_token"
+
+// SetRefreshCookie attaches the refresh token as an HttpOnly, Secure,
+// SameSite=Lax cookie alongside the JSON body, so cookie-based clients and
+// bearer-token clients can both complete the refresh flow with the same
+// endpoint.
+func SetRefreshCookie(c *fiber.Ctx, token string, expiresAt time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     RefreshCookieName,
+		Value:    token,
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Path:     "/",
+	})
+}
+
+// ClearRefreshCookie expires the refresh cookie immediately, used by logout.
+func ClearRefreshCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     RefreshCookieName,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Path:     "/",
+	})
+}
+
+// RefreshTokenFromRequest returns the refresh token from the JSON body field
+// when one was sent, falling back to the cookie for browser clients.
+func RefreshTokenFromRequest(c *fiber.Ctx, bodyToken string) string {
+	if bodyToken != "" {
+		return bodyToken
+	}
+	return c.Cookies(RefreshCookieName)
+}
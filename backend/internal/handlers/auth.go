@@ -1,25 +1,33 @@
 package handlers
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/jagadeesh/grainlify/backend/internal/apierr"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	// Blank-imported so the built-in chain verifiers self-register via
+	// init() before the first request hits Verify().
+	_ "github.com/jagadeesh/grainlify/backend/internal/auth/verifiers"
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/exts"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
 )
 
 type AuthHandler struct {
 	cfg config.Config
 	db  *db.DB
+	bus *natsbus.Bus
 }
 
-func NewAuthHandler(cfg config.Config, d *db.DB) *AuthHandler {
-	return &AuthHandler{cfg: cfg, db: d}
+func NewAuthHandler(cfg config.Config, d *db.DB, bus *natsbus.Bus) *AuthHandler {
+	return &AuthHandler{cfg: cfg, db: d, bus: bus}
 }
 
 type nonceRequest struct {
@@ -30,26 +38,30 @@ type nonceRequest struct {
 func (h *AuthHandler) Nonce() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return apierr.Unavailable("db_not_configured", "database is not configured")
 		}
 
 		var req nonceRequest
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+			return apierr.BadRequest("invalid_json", "request body is not valid JSON")
 		}
 
 		wType, err := auth.NormalizeWalletType(req.WalletType)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			return apierr.BadRequest("invalid_wallet_type", "wallet_type is not supported")
 		}
-		addr, err := auth.NormalizeAddress(wType, req.Address)
+		verifier, err := auth.GetVerifier(wType)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+			return apierr.BadRequest("invalid_wallet_type", "wallet_type is not supported")
+		}
+		addr, err := verifier.NormalizeAddress(req.Address)
+		if err != nil {
+			return apierr.BadRequest("invalid_address", "address is not valid for the given wallet_type")
 		}
 
 		n, err := auth.CreateNonce(c.Context(), h.db.Pool, wType, addr, 10*time.Minute)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "nonce_create_failed"})
+			return apierr.Internal("nonce_create_failed", "failed to create login nonce", err)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -71,62 +83,80 @@ type verifyRequest struct {
 func (h *AuthHandler) Verify() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return apierr.Unavailable("db_not_configured", "database is not configured")
 		}
 		if h.cfg.JWTSecret == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+			return apierr.Unavailable("jwt_not_configured", "JWT signing is not configured")
 		}
 
 		var req verifyRequest
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+			return apierr.BadRequest("invalid_json", "request body is not valid JSON")
 		}
 
 		wType, err := auth.NormalizeWalletType(req.WalletType)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			return apierr.BadRequest("invalid_wallet_type", "wallet_type is not supported")
 		}
-		addr, err := auth.NormalizeAddress(wType, req.Address)
+		verifier, err := auth.GetVerifier(wType)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+			return apierr.BadRequest("invalid_wallet_type", "wallet_type is not supported")
+		}
+		addr, err := verifier.NormalizeAddress(req.Address)
+		if err != nil {
+			return apierr.BadRequest("invalid_address", "address is not valid for the given wallet_type")
 		}
 		if req.Nonce == "" || req.Signature == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_nonce_or_signature"})
+			return apierr.BadRequest("missing_nonce_or_signature", "nonce and signature are required")
 		}
 
-		// Be tolerant during early dev: accept both the current canonical message and the
-		// legacy newline message (so signing tools that copied `\n` vs newline don't block you).
-		msgs := []string{
-			auth.LoginMessage(req.Nonce),
-			auth.LegacyLoginMessage(req.Nonce),
-		}
+		// Be tolerant during early dev: accept both the current canonical message and any
+		// legacy message the verifier still recognizes (so signing tools that copied `\n`
+		// vs newline don't block you).
+		msgs := append([]string{verifier.CanonicalMessage(req.Nonce)}, verifier.LegacyMessages(req.Nonce)...)
 		var sigOK bool
 		for _, msg := range msgs {
-			if err := auth.VerifySignature(wType, addr, msg, req.Signature, req.PublicKey); err == nil {
+			if err := verifier.Verify(addr, msg, req.Signature, req.PublicKey); err == nil {
 				sigOK = true
 				break
 			}
 		}
 		if !sigOK {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+			return apierr.Unauthorized("invalid_signature", "signature does not match the given address")
 		}
 
 		res, err := auth.ConsumeNonceAndUpsertUser(c.Context(), h.db.Pool, wType, addr, req.Nonce, req.PublicKey)
 		if err != nil {
 			if err.Error() == "invalid_or_expired_nonce" {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_or_expired_nonce"})
+				return apierr.Unauthorized("invalid_or_expired_nonce", "nonce is invalid or has expired")
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_failed"})
+			return apierr.Internal("auth_failed", "failed to complete authentication", err)
 		}
 
 		token, err := auth.IssueJWT(h.cfg.JWTSecret, res.User.ID, res.User.Role, res.Wallet.WalletType, res.Wallet.Address, 15*time.Minute)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+			return apierr.Internal("token_issue_failed", "failed to issue access token", err)
+		}
+
+		refreshToken, _, err := auth.CreateSession(c.Context(), h.db.Pool, res.User.ID, res.Wallet.ID, c.Get(fiber.HeaderUserAgent), c.IP())
+		if err != nil {
+			return apierr.Internal("session_create_failed", "failed to create refresh session", err)
+		}
+		exts.SetRefreshCookie(c, refreshToken, time.Now().Add(auth.RefreshTokenTTL))
+
+		if err := h.bus.PublishEvent(c.Context(), "auth.user.logged_in", "auth.user.logged_in", fiber.Map{
+			"user_id":     res.User.ID,
+			"wallet_type": res.Wallet.WalletType,
+			"address":     res.Wallet.Address,
+			"is_new_user": res.IsNewUser,
+		}); err != nil {
+			slog.Warn("failed to publish auth.user.logged_in event", "error", err, "user_id", res.User.ID)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"token": token,
-			"user":  res.User,
+			"token":         token,
+			"refresh_token": refreshToken,
+			"user":          res.User,
 			"wallet": fiber.Map{
 				"wallet_type": res.Wallet.WalletType,
 				"address":     res.Wallet.Address,
@@ -135,17 +165,120 @@ func (h *AuthHandler) Verify() fiber.Handler {
 	}
 }
 
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Refresh rotates an opaque refresh token for a new one and issues a fresh
+// short-lived access JWT. Presenting a refresh token that was already
+// rotated is treated as reuse and revokes the whole session chain.
+func (h *AuthHandler) Refresh() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return apierr.Unavailable("db_not_configured", "database is not configured")
+		}
+		if h.cfg.JWTSecret == "" {
+			return apierr.Unavailable("jwt_not_configured", "JWT signing is not configured")
+		}
+
+		var req refreshRequest
+		_ = c.BodyParser(&req)
+		refreshToken := exts.RefreshTokenFromRequest(c, req.RefreshToken)
+		if refreshToken == "" {
+			return apierr.BadRequest("missing_refresh_token", "refresh_token is required")
+		}
+
+		newToken, session, err := auth.RotateSession(c.Context(), h.db.Pool, refreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+		if err != nil {
+			exts.ClearRefreshCookie(c)
+			if errors.Is(err, auth.ErrRefreshTokenReused) {
+				return apierr.Unauthorized("refresh_token_reused", "refresh token has already been used; all sessions revoked")
+			}
+			if errors.Is(err, auth.ErrInvalidRefreshToken) {
+				return apierr.Unauthorized("invalid_refresh_token", "refresh token is invalid or has expired")
+			}
+			return apierr.Internal("session_refresh_failed", "failed to refresh session", err)
+		}
+
+		identity, err := auth.LoadSessionIdentity(c.Context(), h.db.Pool, session.UserID, session.WalletID)
+		if err != nil {
+			return apierr.Internal("user_lookup_failed", "failed to load user for session", err)
+		}
+
+		accessToken, err := auth.IssueJWT(h.cfg.JWTSecret, identity.UserID, identity.Role, identity.WalletType, identity.Address, 15*time.Minute)
+		if err != nil {
+			return apierr.Internal("token_issue_failed", "failed to issue access token", err)
+		}
+		exts.SetRefreshCookie(c, newToken, time.Now().Add(auth.RefreshTokenTTL))
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token":         accessToken,
+			"refresh_token": newToken,
+		})
+	}
+}
+
+// Logout revokes the session chain behind the presented refresh token.
+func (h *AuthHandler) Logout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return apierr.Unavailable("db_not_configured", "database is not configured")
+		}
+
+		var req refreshRequest
+		_ = c.BodyParser(&req)
+		refreshToken := exts.RefreshTokenFromRequest(c, req.RefreshToken)
+		exts.ClearRefreshCookie(c)
+		if refreshToken == "" {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+		}
+
+		session, err := auth.SessionByRefreshToken(c.Context(), h.db.Pool, refreshToken)
+		if err != nil {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+		}
+		if err := auth.RevokeChain(c.Context(), h.db.Pool, session.ID); err != nil {
+			return apierr.Internal("logout_failed", "failed to revoke session", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// LogoutAll revokes every session for the authenticated user, signing them
+// out of every device.
+func (h *AuthHandler) LogoutAll() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return apierr.Unavailable("db_not_configured", "database is not configured")
+		}
+
+		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return apierr.Unauthorized("invalid_user", "could not resolve authenticated user")
+		}
+
+		exts.ClearRefreshCookie(c)
+		if err := auth.RevokeAllForUser(c.Context(), h.db.Pool, userID); err != nil {
+			return apierr.Internal("logout_all_failed", "failed to revoke sessions", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
 func (h *AuthHandler) Me() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return apierr.Unavailable("db_not_configured", "database is not configured")
 		}
 
 		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
 		role, _ := c.Locals(auth.LocalRole).(string)
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return apierr.Unauthorized("invalid_user", "could not resolve authenticated user")
 		}
 
 		// Get user profile fields from database
@@ -297,5 +430,3 @@ WHERE user_id = $1
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 }
-
-
@@ -8,7 +8,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/jagadeesh/grainlify/backend/internal/apierr"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
@@ -19,10 +21,11 @@ const grainlifyApplicationPrefix = "[grainlify application]"
 type IssueApplicationsHandler struct {
 	cfg config.Config
 	db  *db.DB
+	bus *natsbus.Bus
 }
 
-func NewIssueApplicationsHandler(cfg config.Config, d *db.DB) *IssueApplicationsHandler {
-	return &IssueApplicationsHandler{cfg: cfg, db: d}
+func NewIssueApplicationsHandler(cfg config.Config, d *db.DB, bus *natsbus.Bus) *IssueApplicationsHandler {
+	return &IssueApplicationsHandler{cfg: cfg, db: d, bus: bus}
 }
 
 type applyToIssueRequest struct {
@@ -32,42 +35,42 @@ type applyToIssueRequest struct {
 func (h *IssueApplicationsHandler) Apply() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return apierr.Unavailable("db_not_configured", "database is not configured")
 		}
 		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "token_encryption_not_configured"})
+			return apierr.Unavailable("token_encryption_not_configured", "token encryption is not configured")
 		}
 
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return apierr.BadRequest("invalid_project_id", "project id is not a valid UUID")
 		}
 		issueNumber, err := c.ParamsInt("number")
 		if err != nil || issueNumber <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_issue_number"})
+			return apierr.BadRequest("invalid_issue_number", "issue number must be a positive integer")
 		}
 
 		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return apierr.Unauthorized("invalid_user", "could not resolve authenticated user")
 		}
 
 		var req applyToIssueRequest
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+			return apierr.BadRequest("invalid_body", "request body is not valid JSON")
 		}
 		req.Message = strings.TrimSpace(req.Message)
 		if req.Message == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message_required"})
+			return apierr.BadRequest("message_required", "message is required")
 		}
 		if len(req.Message) > 5000 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message_too_long"})
+			return apierr.BadRequest("message_too_long", "message must be 5000 characters or fewer")
 		}
 
 		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+			return apierr.BadRequest("github_not_linked", "a GitHub account must be linked before applying to issues")
 		}
 
 		// Load repo + issue state from DB.
@@ -83,21 +86,21 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
   AND gi.number = $2
 LIMIT 1
 `, projectID, issueNumber).Scan(&fullName, &state, &authorLogin, &assigneesJSON); err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "issue_not_found"})
+			return apierr.NotFound("issue_not_found", "issue not found on a verified project")
 		}
 
 		if strings.ToLower(strings.TrimSpace(state)) != "open" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "issue_not_open"})
+			return apierr.BadRequest("issue_not_open", "issue is not open")
 		}
 		if strings.EqualFold(strings.TrimSpace(authorLogin), strings.TrimSpace(linked.Login)) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_apply_to_own_issue"})
+			return apierr.BadRequest("cannot_apply_to_own_issue", "cannot apply to your own issue")
 		}
 
 		// "yet to be assigned" => no assignees.
 		var assignees []any
 		_ = json.Unmarshal(assigneesJSON, &assignees)
 		if len(assignees) > 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "issue_already_assigned"})
+			return apierr.BadRequest("issue_already_assigned", "issue already has an assignee")
 		}
 
 		// Create GitHub comment as the applicant (OAuth token).
@@ -105,15 +108,12 @@ LIMIT 1
 		gh := github.NewClient()
 		ghComment, err := gh.CreateIssueComment(c.Context(), linked.AccessToken, fullName, issueNumber, commentBody)
 		if err != nil {
-			slog.Warn("failed to create github issue comment for application",
-				"project_id", projectID.String(),
-				"issue_number", issueNumber,
-				"github_full_name", fullName,
-				"user_id", userID.String(),
-				"github_login", linked.Login,
-				"error", err,
-			)
-			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "github_comment_create_failed"})
+			return apierr.Upstream("github_comment_create_failed", err).WithDetails(map[string]any{
+				"project_id":       projectID.String(),
+				"issue_number":     issueNumber,
+				"github_full_name": fullName,
+				"github_login":     linked.Login,
+			})
 		}
 
 		// Persist the comment into our DB so maintainers see it immediately.
@@ -127,6 +127,18 @@ SET comments = COALESCE(comments, '[]'::jsonb) || $3::jsonb,
 WHERE project_id = $1 AND number = $2
 `, projectID, issueNumber, commentJSON, ghComment.UpdatedAt)
 
+		if err := h.bus.PublishEvent(c.Context(), "issue_application.created", "issue_application.created", fiber.Map{
+			"project_id":        projectID,
+			"issue_number":      issueNumber,
+			"github_full_name":  fullName,
+			"applicant_user_id": userID,
+			"applicant_login":   linked.Login,
+			"comment_id":        ghComment.ID,
+		}); err != nil {
+			slog.Warn("failed to publish issue_application.created event",
+				"error", err, "project_id", projectID, "issue_number", issueNumber)
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"ok": true,
 			"comment": fiber.Map{
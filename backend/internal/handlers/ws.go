@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/realtime"
+)
+
+const (
+	wsPingInterval = 15 * time.Second
+	wsIdleTimeout  = 30 * time.Second
+)
+
+// WSHandler upgrades connections to the per-issue live feed: new
+// applications and GitHub issue/comment activity are pushed to every
+// maintainer watching that issue without polling.
+type WSHandler struct {
+	cfg config.Config
+	hub *realtime.Hub
+}
+
+func NewWSHandler(cfg config.Config, hub *realtime.Hub) *WSHandler {
+	return &WSHandler{cfg: cfg, hub: hub}
+}
+
+// Upgrade is the pre-upgrade Fiber middleware that authenticates the
+// connection and ensures it's actually a WebSocket handshake, matching the
+// gofiber/contrib/websocket pattern of a guard handler in front of
+// websocket.New.
+func (h *WSHandler) Upgrade() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		token := wsToken(c)
+		if token == "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing_token")
+		}
+		claims, err := auth.ParseJWT(h.cfg.JWTSecret, token)
+		if err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid_token")
+		}
+		c.Locals(auth.LocalUserID, claims.UserID)
+		c.Locals(auth.LocalRole, claims.Role)
+
+		return c.Next()
+	}
+}
+
+// wsToken pulls the bearer token from the Sec-WebSocket-Protocol header (the
+// convention most WebSocket client libraries use to carry auth, since
+// Authorization headers aren't available in browser WebSocket APIs) or
+// falls back to a ?token= query param.
+func wsToken(c *fiber.Ctx) string {
+	if proto := c.Get("Sec-WebSocket-Protocol"); proto != "" {
+		parts := strings.Split(proto, ",")
+		if len(parts) > 0 {
+			return strings.TrimSpace(parts[0])
+		}
+	}
+	return c.Query("token")
+}
+
+// Feed streams the live feed for a single project issue. Register it behind
+// Upgrade(), e.g.:
+//
+//	app.Get("/ws/projects/:id/issues/:number", wsHandler.Upgrade(), websocket.New(wsHandler.Feed()))
+func (h *WSHandler) Feed() func(*websocket.Conn) {
+	return func(conn *websocket.Conn) {
+		projectID := conn.Params("id")
+		issueNumber := conn.Params("number")
+		key := realtime.IssueKey(projectID, issueNumberOrZero(issueNumber))
+
+		client := h.hub.Join(key)
+		defer h.hub.Leave(client)
+
+		done := make(chan struct{})
+		go writePump(conn, client, done)
+		readPump(conn, done)
+	}
+}
+
+func issueNumberOrZero(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+// writePump flushes hub frames to the client and pings on an interval, until
+// the hub closes client.Send or a write fails.
+func writePump(conn *websocket.Conn, client *realtime.Client, done chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	defer conn.Close()
+
+	for {
+		select {
+		case <-done:
+			return
+		case msg, ok := <-client.Send:
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, nil)
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(wsIdleTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsIdleTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump only exists to detect the client going away: pong resets the
+// idle deadline, and any read error (close frame, network drop, or the
+// deadline firing) ends the connection.
+func readPump(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			slog.Debug("websocket read loop ending", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Down rolls back up to steps migrations. steps must be greater than zero.
+func Down(ctx context.Context, pool *pgxpool.Pool, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be greater than zero")
+	}
+
+	m, closeFn, err := open(pool)
+	if err != nil {
+		slog.Error("failed to open migrator", "error", err)
+		return err
+	}
+	defer closeFn()
+
+	from, dirty, err := currentVersion(m)
+	if err != nil {
+		slog.Warn("could not get current migration version", "error", err)
+	} else {
+		slog.Info("current migration version", "version", from, "dirty", dirty)
+	}
+
+	_ = ctx
+
+	slog.Info("rolling back database migrations", "steps", steps)
+	if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+		slog.Error("rollback failed", "error", err)
+		return err
+	} else if err == migrate.ErrNoChange {
+		slog.Info("nothing to roll back")
+		return nil
+	}
+
+	to, _, verErr := currentVersion(m)
+	if verErr == nil {
+		slog.Info("rollback completed successfully", "from", from, "to", to)
+	} else {
+		slog.Info("rollback completed successfully")
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrate
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Force sets the migrator's recorded version without running any migration,
+// clearing the dirty flag. Used to recover from a migration that panicked or
+// was killed mid-run and left the schema_migrations table dirty.
+func Force(ctx context.Context, pool *pgxpool.Pool, version int) error {
+	m, closeFn, err := open(pool)
+	if err != nil {
+		slog.Error("failed to open migrator", "error", err)
+		return err
+	}
+	defer closeFn()
+
+	_ = ctx
+
+	slog.Info("forcing migration version", "version", version)
+	if err := m.Force(version); err != nil {
+		slog.Error("force failed", "error", err)
+		return err
+	}
+
+	slog.Info("forced migration version successfully", "version", version)
+	return nil
+}
@@ -0,0 +1,41 @@
+package migrate
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Goto migrates the database to the exact version given, up or down as
+// needed.
+func Goto(ctx context.Context, pool *pgxpool.Pool, version uint) error {
+	m, closeFn, err := open(pool)
+	if err != nil {
+		slog.Error("failed to open migrator", "error", err)
+		return err
+	}
+	defer closeFn()
+
+	from, dirty, err := currentVersion(m)
+	if err != nil {
+		slog.Warn("could not get current migration version", "error", err)
+	} else {
+		slog.Info("current migration version", "version", from, "dirty", dirty)
+	}
+
+	_ = ctx
+
+	slog.Info("migrating to version", "from", from, "to", version)
+	if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+		slog.Error("goto migration failed", "error", err)
+		return err
+	} else if err == migrate.ErrNoChange {
+		slog.Info("already at target version", "version", version)
+		return nil
+	}
+
+	slog.Info("goto migration completed successfully", "from", from, "to", version)
+	return nil
+}
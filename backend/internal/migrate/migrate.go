@@ -14,61 +14,66 @@ import (
 	"github.com/jagadeesh/grainlify/backend/migrations"
 )
 
-func Up(ctx context.Context, pool *pgxpool.Pool) error {
+// open builds a *migrate.Migrate instance against pool, sharing the same
+// embedded-source + postgres-driver boilerplate across every entry point in
+// this package. Callers must call the returned close func once they're done
+// with the migrator.
+func open(pool *pgxpool.Pool) (m *migrate.Migrate, closeFn func(), err error) {
 	if pool == nil {
-		return fmt.Errorf("db pool is nil")
+		return nil, nil, fmt.Errorf("db pool is nil")
 	}
 
-	slog.Info("loading embedded migration files")
 	src, err := iofs.New(migrations.FS, ".")
 	if err != nil {
-		slog.Error("failed to load embedded migrations",
-			"error", err,
-			"error_type", fmt.Sprintf("%T", err),
-		)
-		return fmt.Errorf("open embedded migrations: %w", err)
+		return nil, nil, fmt.Errorf("open embedded migrations: %w", err)
 	}
-	slog.Info("embedded migrations loaded")
 
-	slog.Info("opening database connection for migrations")
 	sqlDB := stdlib.OpenDB(*pool.Config().ConnConfig)
-	defer sqlDB.Close()
 
-	slog.Info("creating postgres migration driver")
 	db, err := postgres.WithInstance(sqlDB, &postgres.Config{})
 	if err != nil {
-		slog.Error("failed to create postgres migration driver",
-			"error", err,
-			"error_type", fmt.Sprintf("%T", err),
-		)
-		return fmt.Errorf("create postgres migration driver: %w", err)
+		sqlDB.Close()
+		return nil, nil, fmt.Errorf("create postgres migration driver: %w", err)
 	}
 
-	slog.Info("creating migrator instance")
-	m, err := migrate.NewWithInstance("iofs", src, "postgres", db)
+	m, err = migrate.NewWithInstance("iofs", src, "postgres", db)
 	if err != nil {
-		slog.Error("failed to create migrator",
-			"error", err,
-			"error_type", fmt.Sprintf("%T", err),
-		)
-		return fmt.Errorf("create migrator: %w", err)
+		sqlDB.Close()
+		return nil, nil, fmt.Errorf("create migrator: %w", err)
 	}
-	defer func() {
-		slog.Info("closing migrator")
+
+	return m, func() {
 		_, _ = m.Close()
-	}()
-
-	// Check current version before migrating
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
-		slog.Warn("could not get current migration version",
-			"error", err,
-		)
+		sqlDB.Close()
+	}, nil
+}
+
+// currentVersion returns the migrator's current version, treating
+// ErrNilVersion (no migrations applied yet) as version 0, not dirty.
+func currentVersion(m *migrate.Migrate) (version uint, dirty bool, err error) {
+	v, dirty, err := m.Version()
+	if err != nil {
+		if err == migrate.ErrNilVersion {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return v, dirty, nil
+}
+
+func Up(ctx context.Context, pool *pgxpool.Pool) error {
+	m, closeFn, err := open(pool)
+	if err != nil {
+		slog.Error("failed to open migrator", "error", err)
+		return err
+	}
+	defer closeFn()
+
+	from, dirty, err := currentVersion(m)
+	if err != nil {
+		slog.Warn("could not get current migration version", "error", err)
 	} else {
-		slog.Info("current migration version",
-			"version", version,
-			"dirty", dirty,
-		)
+		slog.Info("current migration version", "version", from, "dirty", dirty)
 	}
 
 	// migrate.Up() is not context-aware; we still accept ctx for future evolutions.
@@ -76,28 +81,19 @@ func Up(ctx context.Context, pool *pgxpool.Pool) error {
 
 	slog.Info("running database migrations")
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		slog.Error("migration failed",
-			"error", err,
-			"error_type", fmt.Sprintf("%T", err),
-		)
+		slog.Error("migration failed", "error", err)
 		return err
+	} else if err == migrate.ErrNoChange {
+		slog.Info("migrations up to date, no changes needed")
+		return nil
 	}
 
-	if err == migrate.ErrNoChange {
-		slog.Info("migrations up to date, no changes needed")
+	to, _, verErr := currentVersion(m)
+	if verErr == nil {
+		slog.Info("migrations completed successfully", "from", from, "to", to)
 	} else {
-		// Get version after migration
-		newVersion, _, verErr := m.Version()
-		if verErr == nil {
-			slog.Info("migrations completed successfully",
-				"new_version", newVersion,
-			)
-		} else {
-			slog.Info("migrations completed successfully")
-		}
+		slog.Info("migrations completed successfully")
 	}
 
 	return nil
 }
-
-
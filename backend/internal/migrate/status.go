@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/migrations"
+)
+
+// Status reports the current migration version, whether the schema is
+// dirty, and the versions available in the embedded source that are newer
+// than current.
+func Status(ctx context.Context, pool *pgxpool.Pool) (current uint, dirty bool, pending []uint, err error) {
+	m, closeFn, err := open(pool)
+	if err != nil {
+		slog.Error("failed to open migrator", "error", err)
+		return 0, false, nil, err
+	}
+	defer closeFn()
+
+	_ = ctx
+
+	current, dirty, err = currentVersion(m)
+	if err != nil {
+		return 0, false, nil, fmt.Errorf("get current version: %w", err)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return current, dirty, nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+
+	pending, err = pendingVersions(src, current)
+	if err != nil {
+		return current, dirty, nil, fmt.Errorf("list pending versions: %w", err)
+	}
+
+	slog.Info("migration status", "current", current, "dirty", dirty, "pending", len(pending))
+	return current, dirty, pending, nil
+}
+
+// pendingVersions walks the source driver's version list and returns every
+// version strictly greater than after.
+func pendingVersions(src source.Driver, after uint) ([]uint, error) {
+	var versions []uint
+
+	version, err := src.First()
+	if err != nil {
+		if errors.Is(err, source.ErrNotExist) {
+			return versions, nil
+		}
+		return nil, err
+	}
+
+	for {
+		if version > after {
+			versions = append(versions, version)
+		}
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, source.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		version = next
+	}
+
+	return versions, nil
+}
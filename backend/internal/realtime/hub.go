@@ -0,0 +1,220 @@
+// Package realtime fans domain events out to WebSocket clients watching a
+// single project issue. A Hub is fed by a NATS subscription so every API
+// replica broadcasts the same frames, instead of only the replica that
+// happened to handle the write.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+)
+
+// sendBufferSize bounds how many frames a slow client can fall behind by
+// before the hub gives up on it and closes the connection.
+const sendBufferSize = 16
+
+// Frame is the JSON message streamed to WebSocket clients.
+type Frame struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Client is a single subscribed WebSocket connection. Send is buffered; the
+// hub's run loop drops the connection rather than blocking when it's full.
+type Client struct {
+	Send   chan []byte
+	key    string
+	closed bool
+}
+
+func newClient() *Client {
+	return &Client{Send: make(chan []byte, sendBufferSize)}
+}
+
+// Close is idempotent. It is called only from the hub's run loop (on
+// unregister or on overflow), so the closed flag is not locked.
+func (c *Client) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.Send)
+}
+
+// Hub tracks subscribers per issue and fans out frames to them.
+type Hub struct {
+	register   chan *subscription
+	unregister chan *subscription
+	broadcast  chan broadcastMsg
+}
+
+type subscription struct {
+	key    string
+	client *Client
+}
+
+type broadcastMsg struct {
+	key   string
+	frame []byte
+}
+
+// IssueKey identifies the per-issue subscriber set a WebSocket connection
+// joins.
+func IssueKey(projectID string, issueNumber int) string {
+	return fmt.Sprintf("%s:%d", projectID, issueNumber)
+}
+
+// NewHub builds a Hub and starts its run loop. It does not itself connect to
+// NATS; call Hub.ConsumeFrom to wire it to a bus so events published by any
+// API replica reach this replica's subscribers.
+func NewHub() *Hub {
+	h := &Hub{
+		register:   make(chan *subscription),
+		unregister: make(chan *subscription),
+		broadcast:  make(chan broadcastMsg, 256),
+	}
+	go h.run()
+	return h
+}
+
+func (h *Hub) run() {
+	subscribers := make(map[string]map[*Client]struct{})
+
+	for {
+		select {
+		case sub := <-h.register:
+			if subscribers[sub.key] == nil {
+				subscribers[sub.key] = make(map[*Client]struct{})
+			}
+			subscribers[sub.key][sub.client] = struct{}{}
+
+		case sub := <-h.unregister:
+			if clients, ok := subscribers[sub.key]; ok {
+				if _, ok := clients[sub.client]; ok {
+					delete(clients, sub.client)
+					sub.client.Close()
+				}
+				if len(clients) == 0 {
+					delete(subscribers, sub.key)
+				}
+			}
+
+		case msg := <-h.broadcast:
+			for client := range subscribers[msg.key] {
+				select {
+				case client.Send <- msg.frame:
+				default:
+					// Client is too far behind; drop it instead of blocking
+					// every other subscriber on this issue.
+					delete(subscribers[msg.key], client)
+					client.Close()
+				}
+			}
+		}
+	}
+}
+
+// Join registers a new subscriber for key and returns the Client to pump
+// frames through. Call Leave when the connection closes.
+func (h *Hub) Join(key string) *Client {
+	client := newClient()
+	client.key = key
+	h.register <- &subscription{key: key, client: client}
+	return client
+}
+
+// Leave unregisters client from its subscriber set.
+func (h *Hub) Leave(client *Client) {
+	h.unregister <- &subscription{key: client.key, client: client}
+}
+
+// Publish fans a frame out to every subscriber of key.
+func (h *Hub) Publish(key string, frame Frame) {
+	payload, err := json.Marshal(frame)
+	if err != nil {
+		slog.Error("failed to marshal realtime frame", "error", err, "key", key, "type", frame.Type)
+		return
+	}
+	h.broadcast <- broadcastMsg{key: key, frame: payload}
+}
+
+// issueApplicationEvent is the subset of issue_application.created event
+// data needed to route it to the right issue's subscribers and describe it
+// to the client.
+type issueApplicationEvent struct {
+	ProjectID       string `json:"project_id"`
+	IssueNumber     int    `json:"issue_number"`
+	GithubFullName  string `json:"github_full_name"`
+	ApplicantUserID string `json:"applicant_user_id"`
+	ApplicantLogin  string `json:"applicant_login"`
+	CommentID       int64  `json:"comment_id"`
+}
+
+// githubIssueEvent is the subset of github.issue.* event data needed to
+// route an upstream issue change to the right subscribers.
+type githubIssueEvent struct {
+	ProjectID   string `json:"project_id"`
+	IssueNumber int    `json:"issue_number"`
+}
+
+// ConsumeFrom durably subscribes the hub to the subjects it fans out to
+// WebSocket clients, translating each NATS event into the frame type
+// clients expect. Multiple API replicas can each call this with their own
+// durable consumer name so every replica's hub (and thus every connected
+// client, regardless of which replica they're attached to) sees the same
+// events.
+func (h *Hub) ConsumeFrom(bus *natsbus.Bus, durable string) error {
+	if _, err := bus.Subscribe("issue_application.*", durable+"-issue-application", func(ctx context.Context, data []byte) error {
+		var evt natsbus.Event
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(evt.Data)
+		if err != nil {
+			return err
+		}
+		var payload issueApplicationEvent
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return err
+		}
+		h.Publish(IssueKey(payload.ProjectID, payload.IssueNumber), Frame{
+			Type: "application_created",
+			Data: payload,
+		})
+		return nil
+	}); err != nil {
+		return fmt.Errorf("subscribe issue_application.*: %w", err)
+	}
+
+	if _, err := bus.Subscribe("github.issue.*", durable+"-github-issue", func(ctx context.Context, data []byte) error {
+		var evt natsbus.Event
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(evt.Data)
+		if err != nil {
+			return err
+		}
+		var payload githubIssueEvent
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return err
+		}
+		frameType := "issue_updated"
+		if evt.Type == "github.issue.comment_created" {
+			frameType = "comment_created"
+		}
+		h.Publish(IssueKey(payload.ProjectID, payload.IssueNumber), Frame{
+			Type: frameType,
+			Data: payload,
+		})
+		return nil
+	}); err != nil {
+		return fmt.Errorf("subscribe github.issue.*: %w", err)
+	}
+
+	return nil
+}
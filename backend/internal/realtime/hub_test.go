@@ -0,0 +1,24 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+)
+
+// TestConsumeFromSubjectsAreCoveredByStream guards against ConsumeFrom's
+// consumer filters drifting out of the grainlify stream's subject space: a
+// PullSubscribe whose filter doesn't overlap any stream subject fails to
+// bind, so the corresponding half of the live feed would never wire up.
+func TestConsumeFromSubjectsAreCoveredByStream(t *testing.T) {
+	sampleSubjects := map[string]string{
+		"issue_application.*": "issue_application.created",
+		"github.issue.*":      "github.issue.updated",
+	}
+
+	for filter, sample := range sampleSubjects {
+		if !natsbus.SubjectCoveredByStream(sample) {
+			t.Errorf("consumer filter %q (sample subject %q) is not covered by the grainlify stream's subjects", filter, sample)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migration files applied by the
+// internal/migrate package.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS